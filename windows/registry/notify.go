@@ -0,0 +1,121 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"sync"
+	"syscall"
+)
+
+// NotifyFilter is a bitmask of the kinds of changes a Notify watch should
+// report, mirroring the REG_NOTIFY_CHANGE_* flags accepted by
+// RegNotifyChangeKeyValue.
+type NotifyFilter uint32
+
+const (
+	// NotifyChangeName fires when a subkey is added or deleted.
+	NotifyChangeName NotifyFilter = 0x00000001
+	// NotifyChangeAttributes fires when the attributes of a key change.
+	NotifyChangeAttributes NotifyFilter = 0x00000002
+	// NotifyChangeLastSet fires when a value under the key is added,
+	// deleted, or modified.
+	NotifyChangeLastSet NotifyFilter = 0x00000004
+	// NotifyChangeSecurity fires when the security descriptor of a key
+	// changes.
+	NotifyChangeSecurity NotifyFilter = 0x00000008
+
+	// regNotifyThreadAgnostic lets the wait complete on any thread,
+	// matching REG_NOTIFY_THREAD_AGNOSTIC. It is always added by Notify
+	// so callers don't need a dedicated waiter thread per watch.
+	regNotifyThreadAgnostic NotifyFilter = 0x10000000
+)
+
+// NotifyEvent is sent on the channel returned by Notify each time one of
+// the watched changes occurs. Err is non-nil only for the final event
+// delivered before the channel is closed, when the watch could not be
+// re-armed or waiting for the next change failed.
+type NotifyEvent struct {
+	Err error
+}
+
+// CancelFunc stops a watch started by Notify. It is safe to call more
+// than once and from any goroutine. The event channel is closed after the
+// watch has fully stopped.
+type CancelFunc func()
+
+// Notify starts watching key k for the changes described by filter,
+// optionally including its entire subtree, and reports them on the
+// returned channel. The watch uses RegNotifyChangeKeyValue with
+// REG_NOTIFY_THREAD_AGNOSTIC and re-arms itself after every delivered
+// event, so callers do not need to poll ReadValueNames or ReadSubKeyNames
+// in a loop to learn about configuration changes written elsewhere, such
+// as by Group Policy.
+//
+// The caller must invoke the returned CancelFunc to release the
+// underlying handles once the watch is no longer needed.
+func (k Key) Notify(filter NotifyFilter, watchSubtree bool) (<-chan NotifyEvent, CancelFunc, error) {
+	changed, err := createEvent(nil, true, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	cancel, err := createEvent(nil, true, false, nil)
+	if err != nil {
+		syscall.CloseHandle(changed)
+		return nil, nil, err
+	}
+
+	events := make(chan NotifyEvent)
+	done := make(chan struct{})
+	var once sync.Once
+	cancelFn := func() {
+		once.Do(func() {
+			close(done)
+			setEvent(cancel)
+		})
+	}
+
+	// send delivers ev to events unless done is closed first, so a
+	// goroutine blocked here because nothing is reading events still
+	// unblocks as soon as cancelFn runs, instead of leaking forever.
+	send := func(ev NotifyEvent) (ok bool) {
+		select {
+		case events <- ev:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	go func() {
+		defer close(events)
+		defer syscall.CloseHandle(changed)
+		defer syscall.CloseHandle(cancel)
+
+		for {
+			err := regNotifyChangeKeyValue(syscall.Handle(k), watchSubtree, uint32(filter|regNotifyThreadAgnostic), changed, true)
+			if err != nil {
+				send(NotifyEvent{Err: err})
+				return
+			}
+			switch n, err := waitForMultipleObjects([]syscall.Handle{changed, cancel}, false, syscall.INFINITE); {
+			case err != nil:
+				send(NotifyEvent{Err: err})
+				return
+			case n == 1:
+				// cancel was signaled
+				return
+			default:
+				resetEvent(changed)
+				if !send(NotifyEvent{}) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, cancelFn, nil
+}
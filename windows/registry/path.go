@@ -0,0 +1,197 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	pathListSeparator = ";"
+
+	hwndBroadcast        = 0xffff
+	wmSettingChange      = 0x001a
+	smtoAbortIfHung      = 0x0002
+	settingChangeTimeout = 5000 // milliseconds
+)
+
+var (
+	moduser32              = syscall.NewLazyDLL("user32.dll")
+	procSendMessageTimeout = moduser32.NewProc("SendMessageTimeoutW")
+)
+
+// PathValue represents a semicolon-delimited value, such as PATH, loaded
+// from the registry. It preserves the original value's type (SZ or
+// EXPAND_SZ) across edits so that Commit does not silently change how the
+// value is interpreted by consumers that expand environment strings.
+type PathValue struct {
+	key     Key
+	name    string
+	valtype uint32
+	entries []string
+}
+
+// EditPathValue loads the named value under k as a PathValue, ready for
+// Append, Prepend, Remove, or Dedupe followed by Commit. If the value does
+// not yet exist, EditPathValue returns an empty PathValue of type
+// EXPAND_SZ, the type Windows itself uses for PATH. If the value exists
+// but is not SZ or EXPAND_SZ, EditPathValue returns ErrUnexpectedType.
+func (k Key) EditPathValue(name string) (*PathValue, error) {
+	data, typ, err := k.getValue(name, make([]byte, 64))
+	if err != nil {
+		if err == ErrNotExist {
+			return &PathValue{key: k, name: name, valtype: EXPAND_SZ}, nil
+		}
+		return nil, err
+	}
+	switch typ {
+	case SZ, EXPAND_SZ:
+	default:
+		return nil, ErrUnexpectedType
+	}
+	pv := &PathValue{key: k, name: name, valtype: typ}
+	if len(data) > 1 {
+		u := (*[1 << 15]uint16)(unsafe.Pointer(&data[0]))[: len(data)/2 : len(data)/2]
+		pv.entries = splitPathList(syscall.UTF16ToString(u))
+	}
+	return pv, nil
+}
+
+func splitPathList(s string) []string {
+	var out []string
+	for _, e := range strings.Split(s, pathListSeparator) {
+		if e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Entries returns a copy of the path entries in their current order.
+func (p *PathValue) Entries() []string {
+	return append([]string(nil), p.entries...)
+}
+
+// Contains reports whether path is already present, using the
+// case-insensitive comparison Windows uses for file system paths.
+func (p *PathValue) Contains(path string) bool {
+	return p.indexOf(path) >= 0
+}
+
+func (p *PathValue) indexOf(path string) int {
+	for i, e := range p.entries {
+		if strings.EqualFold(e, path) {
+			return i
+		}
+	}
+	return -1
+}
+
+func validatePathEntry(path string) error {
+	if strings.IndexByte(path, 0) >= 0 {
+		return errors.New("registry: path entry must not contain a NUL byte")
+	}
+	if strings.Contains(path, pathListSeparator) {
+		return errors.New("registry: path entry must not contain a ';'")
+	}
+	return nil
+}
+
+// Append adds path to the end of the value, unless it is already present.
+func (p *PathValue) Append(path string) error {
+	if err := validatePathEntry(path); err != nil {
+		return err
+	}
+	if p.Contains(path) {
+		return nil
+	}
+	p.entries = append(p.entries, path)
+	return nil
+}
+
+// Prepend adds path to the beginning of the value. If path is already
+// present, compared case-insensitively, its first occurrence is moved to
+// the front with its original spelling instead of adding a duplicate.
+func (p *PathValue) Prepend(path string) error {
+	if err := validatePathEntry(path); err != nil {
+		return err
+	}
+	if i := p.indexOf(path); i >= 0 {
+		path = p.entries[i]
+		p.entries = append(p.entries[:i], p.entries[i+1:]...)
+	}
+	p.entries = append([]string{path}, p.entries...)
+	return nil
+}
+
+// Remove deletes every occurrence of path from the value.
+func (p *PathValue) Remove(path string) {
+	out := p.entries[:0]
+	for _, e := range p.entries {
+		if !strings.EqualFold(e, path) {
+			out = append(out, e)
+		}
+	}
+	p.entries = out
+}
+
+// Dedupe removes duplicate entries, keeping the first occurrence of each,
+// using the case-insensitive comparison Windows uses for file system paths.
+func (p *PathValue) Dedupe() {
+	seen := make(map[string]bool, len(p.entries))
+	out := p.entries[:0]
+	for _, e := range p.entries {
+		key := strings.ToLower(e)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e)
+	}
+	p.entries = out
+}
+
+// Commit writes the value back to the registry using RegSetValueEx
+// directly, preserving the original SZ or EXPAND_SZ type, and then
+// broadcasts WM_SETTINGCHANGE so Explorer and other processes that cache
+// the environment pick up the change. It does not shell out to setx, so
+// it is not subject to setx's 1024-character truncation.
+func (p *PathValue) Commit() error {
+	joined := strings.Join(p.entries, pathListSeparator)
+	v, err := syscall.UTF16FromString(joined)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, len(v)*2)
+	for i, u := range v {
+		buf[2*i], buf[2*i+1] = byte(u), byte(u>>8)
+	}
+	if err := p.key.setValue(p.name, p.valtype, buf); err != nil {
+		return err
+	}
+	broadcastSettingChange()
+	return nil
+}
+
+func broadcastSettingChange() {
+	env, err := syscall.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	procSendMessageTimeout.Call(
+		uintptr(hwndBroadcast),
+		uintptr(wmSettingChange),
+		0,
+		uintptr(unsafe.Pointer(env)),
+		uintptr(smtoAbortIfHung),
+		uintptr(settingChangeTimeout),
+		0,
+	)
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPathList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{`C:\Go\bin`, []string{`C:\Go\bin`}},
+		{`C:\Go\bin;C:\Windows`, []string{`C:\Go\bin`, `C:\Windows`}},
+		{`C:\Go\bin;;C:\Windows`, []string{`C:\Go\bin`, `C:\Windows`}},
+		{`;C:\Go\bin;`, []string{`C:\Go\bin`}},
+	}
+	for _, tt := range tests {
+		if got := splitPathList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPathList(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePathEntry(t *testing.T) {
+	tests := []struct {
+		entry   string
+		wantErr bool
+	}{
+		{`C:\Go\bin`, false},
+		{"C:\\has\x00nul", true},
+		{`C:\has;semicolon`, true},
+	}
+	for _, tt := range tests {
+		err := validatePathEntry(tt.entry)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validatePathEntry(%q) error = %v, wantErr %v", tt.entry, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPathValueAppend(t *testing.T) {
+	p := &PathValue{entries: []string{`C:\A`, `C:\B`}}
+
+	if err := p.Append(`C:\C`); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if want := []string{`C:\A`, `C:\B`, `C:\C`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after Append, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+
+	// Appending an entry that is already present, case-insensitively,
+	// must not duplicate it.
+	if err := p.Append(`c:\a`); err != nil {
+		t.Fatalf("Append duplicate: %v", err)
+	}
+	if want := []string{`C:\A`, `C:\B`, `C:\C`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after duplicate Append, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+
+	if err := p.Append(`bad;entry`); err == nil {
+		t.Error("Append with ';' in entry: got nil error, want error")
+	}
+}
+
+func TestPathValuePrepend(t *testing.T) {
+	p := &PathValue{entries: []string{`C:\A`, `C:\B`}}
+
+	if err := p.Prepend(`C:\C`); err != nil {
+		t.Fatalf("Prepend: %v", err)
+	}
+	if want := []string{`C:\C`, `C:\A`, `C:\B`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after Prepend, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+
+	// Prepending an existing entry moves it to the front instead of
+	// duplicating it.
+	if err := p.Prepend(`c:\b`); err != nil {
+		t.Fatalf("Prepend existing: %v", err)
+	}
+	if want := []string{`C:\B`, `C:\C`, `C:\A`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after Prepend existing, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+}
+
+func TestPathValueRemove(t *testing.T) {
+	p := &PathValue{entries: []string{`C:\A`, `C:\B`, `c:\a`, `C:\C`}}
+	p.Remove(`C:\A`)
+	if want := []string{`C:\B`, `C:\C`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after Remove, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+}
+
+func TestPathValueDedupe(t *testing.T) {
+	p := &PathValue{entries: []string{`C:\A`, `C:\B`, `c:\a`, `C:\A`}}
+	p.Dedupe()
+	if want := []string{`C:\A`, `C:\B`}; !reflect.DeepEqual(p.Entries(), want) {
+		t.Errorf("after Dedupe, Entries() = %#v, want %#v", p.Entries(), want)
+	}
+}
+
+func TestPathValueContains(t *testing.T) {
+	p := &PathValue{entries: []string{`C:\A`, `C:\B`}}
+	if !p.Contains(`c:\a`) {
+		t.Error("Contains(`c:\\a`) = false, want true (case-insensitive)")
+	}
+	if p.Contains(`C:\Z`) {
+		t.Error("Contains(`C:\\Z`) = true, want false")
+	}
+}
@@ -0,0 +1,479 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// regFileHeader is the line every "Registry Editor Version 5.00" .reg file
+// starts with.
+const regFileHeader = "Windows Registry Editor Version 5.00"
+
+const regLineWidth = 80
+
+var rootKeyNames = map[string]Key{
+	"HKEY_CLASSES_ROOT":     CLASSES_ROOT,
+	"HKEY_CURRENT_USER":     CURRENT_USER,
+	"HKEY_LOCAL_MACHINE":    LOCAL_MACHINE,
+	"HKEY_USERS":            USERS,
+	"HKEY_CURRENT_CONFIG":   CURRENT_CONFIG,
+	"HKEY_PERFORMANCE_DATA": PERFORMANCE_DATA,
+}
+
+// ExportOptions controls how Key.Export renders a key's subtree.
+type ExportOptions struct {
+	// Root is the full registry path of k, such as
+	// `HKEY_LOCAL_MACHINE\SOFTWARE\Contoso`, used for the section header
+	// of k itself and of every subkey walked underneath it. Key does not
+	// track the path it was opened with, so callers must supply it.
+	Root string
+}
+
+// Export writes k and every key and value beneath it to w in the standard
+// "Registry Editor Version 5.00" .reg text format, the same format
+// reg.exe export produces. Subkeys are walked recursively via
+// ReadSubKeyNames, and each key's values via ReadValueNames and GetValue.
+func (k Key) Export(w io.Writer, opts ExportOptions) error {
+	var b strings.Builder
+	b.WriteString(regFileHeader)
+	b.WriteString("\r\n")
+	if err := k.exportTo(&b, opts.Root); err != nil {
+		return err
+	}
+	return writeRegText(w, b.String())
+}
+
+func (k Key) exportTo(b *strings.Builder, path string) error {
+	b.WriteString("\r\n[")
+	b.WriteString(path)
+	b.WriteString("]\r\n")
+
+	names, err := k.ReadValueNames(0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	for _, name := range names {
+		if err := k.exportValue(b, name); err != nil {
+			return err
+		}
+	}
+
+	subkeys, err := k.ReadSubKeyNames(0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	for _, sub := range subkeys {
+		ck, err := OpenKey(k, sub, READ)
+		if err != nil {
+			return err
+		}
+		err = ck.exportTo(b, path+`\`+sub)
+		ck.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k Key) exportValue(b *strings.Builder, name string) error {
+	n, typ, err := k.GetValue(name, nil)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, n)
+	if n > 0 {
+		if _, _, err := k.GetValue(name, data); err != nil {
+			return err
+		}
+	}
+
+	if name == "" {
+		b.WriteString("@=")
+	} else {
+		b.WriteString(quoteRegString(name))
+		b.WriteString("=")
+	}
+
+	switch typ {
+	case SZ:
+		b.WriteString(quoteRegString(decodeRegSZ(data)))
+		b.WriteString("\r\n")
+	case DWORD:
+		v := uint32(0)
+		if len(data) >= 4 {
+			v = uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+		}
+		fmt.Fprintf(b, "dword:%08x\r\n", v)
+	case EXPAND_SZ:
+		writeHexValue(b, "hex(2):", data)
+	case MULTI_SZ:
+		writeHexValue(b, "hex(7):", data)
+	case QWORD:
+		writeHexValue(b, "hex(b):", data)
+	case BINARY:
+		writeHexValue(b, "hex:", data)
+	default:
+		writeHexValue(b, fmt.Sprintf("hex(%x):", typ), data)
+	}
+	return nil
+}
+
+func decodeRegSZ(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+	}
+	for len(u) > 0 && u[len(u)-1] == 0 {
+		u = u[:len(u)-1]
+	}
+	return string(utf16.Decode(u))
+}
+
+func quoteRegString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// writeHexValue writes the comma-separated hex byte encoding used for
+// every value type other than SZ and DWORD, continuing past regLineWidth
+// with a trailing backslash the way reg.exe does.
+func writeHexValue(b *strings.Builder, prefix string, data []byte) {
+	b.WriteString(prefix)
+	col := currentColumn(b)
+	for i, bt := range data {
+		tok := fmt.Sprintf("%02x", bt)
+		if i < len(data)-1 {
+			tok += ","
+		}
+		if col+len(tok) > regLineWidth-2 {
+			b.WriteString("\\\r\n  ")
+			col = 2
+		}
+		b.WriteString(tok)
+		col += len(tok)
+	}
+	b.WriteString("\r\n")
+}
+
+// currentColumn returns the number of characters written to b since its
+// last line break.
+func currentColumn(b *strings.Builder) int {
+	s := b.String()
+	if i := strings.LastIndex(s, "\r\n"); i >= 0 {
+		return len(s) - i - 2
+	}
+	return len(s)
+}
+
+// writeRegText encodes s, which must already use CRLF line endings, as
+// UTF-16LE with the byte order mark .reg files require and writes it to w.
+func writeRegText(w io.Writer, s string) error {
+	if _, err := w.Write([]byte{0xff, 0xfe}); err != nil {
+		return err
+	}
+	buf := make([]byte, 0, len(s)*2)
+	for _, v := range utf16.Encode([]rune(s)) {
+		buf = append(buf, byte(v), byte(v>>8))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// Import reads a "Registry Editor Version 5.00" .reg file from r and
+// applies it to the live registry using the existing Set*Value and
+// DeleteValue methods, honoring `-` deletion markers for both keys and
+// values. A `[-key]` section deletes the entire subtree rooted at key,
+// the same as reg.exe. Only the predefined roots listed in rootKeyNames
+// are recognized as section roots.
+func Import(r io.Reader) error {
+	text, err := decodeRegText(r)
+	if err != nil {
+		return err
+	}
+	lines := joinContinuations(strings.Split(text, "\n"))
+
+	var cur Key
+	var curDeleted bool
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, ";") || trimmed == regFileHeader:
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			if cur != 0 {
+				cur.Close()
+				cur = 0
+			}
+			path := trimmed[1 : len(trimmed)-1]
+			curDeleted = strings.HasPrefix(path, "-")
+			if curDeleted {
+				path = path[1:]
+			}
+			root, rest, err := splitRootPath(path)
+			if err != nil {
+				return err
+			}
+			if curDeleted {
+				if rest == "" {
+					return fmt.Errorf("registry: cannot delete a predefined root key")
+				}
+				parent, name := splitParent(rest)
+				pk, err := OpenKey(root, parent, ALL_ACCESS)
+				if err != nil {
+					return err
+				}
+				err = deleteKeyRecursive(pk, name)
+				pk.Close()
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if rest == "" {
+				cur = root
+				continue
+			}
+			k, _, err := CreateKey(root, rest, ALL_ACCESS)
+			if err != nil {
+				return err
+			}
+			cur = k
+		default:
+			if cur == 0 || curDeleted {
+				continue
+			}
+			if err := applyValueLine(cur, trimmed); err != nil {
+				return err
+			}
+		}
+	}
+	if cur != 0 {
+		cur.Close()
+	}
+	return nil
+}
+
+func splitRootPath(path string) (Key, string, error) {
+	i := strings.IndexByte(path, '\\')
+	rootName := path
+	rest := ""
+	if i >= 0 {
+		rootName = path[:i]
+		rest = path[i+1:]
+	}
+	root, ok := rootKeyNames[rootName]
+	if !ok {
+		return 0, "", fmt.Errorf("registry: unrecognized root key %q", rootName)
+	}
+	return root, rest, nil
+}
+
+func splitParent(path string) (parent, name string) {
+	i := strings.LastIndexByte(path, '\\')
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+// deleteKeyRecursive deletes path under base along with every subkey
+// beneath it, depth-first, since DeleteKey itself only removes a key
+// that already has no subkeys.
+func deleteKeyRecursive(base Key, path string) error {
+	k, err := OpenKey(base, path, ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	subkeys, err := k.ReadSubKeyNames(0)
+	if err != nil && err != io.EOF {
+		k.Close()
+		return err
+	}
+	for _, sub := range subkeys {
+		if err := deleteKeyRecursive(k, sub); err != nil {
+			k.Close()
+			return err
+		}
+	}
+	k.Close()
+	return DeleteKey(base, path)
+}
+
+func applyValueLine(k Key, line string) error {
+	var name string
+	var rest string
+	if strings.HasPrefix(line, "@=") {
+		name, rest = "", line[2:]
+	} else if strings.HasPrefix(line, `"`) {
+		end := findUnescapedQuote(line, 1)
+		if end < 0 {
+			return fmt.Errorf("registry: malformed value line %q", line)
+		}
+		name = unquoteRegString(line[1:end])
+		if !strings.HasPrefix(line[end+1:], "=") {
+			return fmt.Errorf("registry: malformed value line %q", line)
+		}
+		rest = line[end+2:]
+	} else {
+		return fmt.Errorf("registry: malformed value line %q", line)
+	}
+
+	if rest == "-" {
+		return k.DeleteValue(name)
+	}
+
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		if len(rest) < 2 || !strings.HasSuffix(rest, `"`) {
+			return fmt.Errorf("registry: malformed string value %q", rest)
+		}
+		return k.SetStringValue(name, unquoteRegString(rest[1:len(rest)-1]))
+	case strings.HasPrefix(rest, "dword:"):
+		v, err := strconv.ParseUint(rest[len("dword:"):], 16, 32)
+		if err != nil {
+			return err
+		}
+		return k.SetDWordValue(name, uint32(v))
+	case strings.HasPrefix(rest, "hex(2):"):
+		data, err := parseHexBytes(rest[len("hex(2):"):])
+		if err != nil {
+			return err
+		}
+		return k.setStringValue(name, EXPAND_SZ, decodeRegSZ(data))
+	case strings.HasPrefix(rest, "hex(7):"):
+		data, err := parseHexBytes(rest[len("hex(7):"):])
+		if err != nil {
+			return err
+		}
+		return k.setValue(name, MULTI_SZ, data)
+	case strings.HasPrefix(rest, "hex(b):"):
+		data, err := parseHexBytes(rest[len("hex(b):"):])
+		if err != nil {
+			return err
+		}
+		return k.setValue(name, QWORD, data)
+	case strings.HasPrefix(rest, "hex:"):
+		data, err := parseHexBytes(rest[len("hex:"):])
+		if err != nil {
+			return err
+		}
+		return k.setValue(name, BINARY, data)
+	case strings.HasPrefix(rest, "hex("):
+		closeIdx := strings.IndexByte(rest, ')')
+		if closeIdx < 0 || !strings.HasPrefix(rest[closeIdx:], "):") {
+			return fmt.Errorf("registry: malformed value type in %q", rest)
+		}
+		typ, err := strconv.ParseUint(rest[len("hex("):closeIdx], 16, 32)
+		if err != nil {
+			return err
+		}
+		data, err := parseHexBytes(rest[closeIdx+2:])
+		if err != nil {
+			return err
+		}
+		return k.setValue(name, uint32(typ), data)
+	default:
+		return fmt.Errorf("registry: unrecognized value data %q", rest)
+	}
+}
+
+func findUnescapedQuote(s string, from int) int {
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteRegString(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+	return r.Replace(s)
+}
+
+func parseHexBytes(s string) ([]byte, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.TrimRight(s, ",")
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]byte, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 16, 8)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = byte(v)
+	}
+	return out, nil
+}
+
+// joinContinuations merges lines ending in a backslash with the line
+// that follows, the way reg.exe wraps long hex values. It does not check
+// whether that trailing backslash is itself escaped.
+func joinContinuations(lines []string) []string {
+	var out []string
+	var cur strings.Builder
+	inCont := false
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r")
+		if inCont {
+			cur.WriteString(strings.TrimLeft(trimmed, " \t"))
+		} else {
+			cur.Reset()
+			cur.WriteString(trimmed)
+		}
+		if strings.HasSuffix(cur.String(), "\\") {
+			s := cur.String()
+			cur.Reset()
+			cur.WriteString(s[:len(s)-1])
+			inCont = true
+			continue
+		}
+		inCont = false
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+// decodeRegText strips the BOM from a .reg file's contents and returns it
+// as a UTF-8 string, accepting the UTF-16LE encoding reg.exe writes as
+// well as plain UTF-8/ASCII for files produced by hand or other tools.
+func decodeRegText(r io.Reader) (string, error) {
+	br := bufio.NewReader(r)
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(data) >= 2 && data[0] == 0xff && data[1] == 0xfe:
+		u := make([]uint16, 0, (len(data)-2)/2)
+		for i := 2; i+1 < len(data); i += 2 {
+			u = append(u, uint16(data[i])|uint16(data[i+1])<<8)
+		}
+		return string(utf16.Decode(u)), nil
+	case len(data) >= 3 && data[0] == 0xef && data[1] == 0xbb && data[2] == 0xbf:
+		return string(data[3:]), nil
+	default:
+		return string(data), nil
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuoteUnquoteRegString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{``, `""`},
+		{`simple`, `"simple"`},
+		{`back\slash`, `"back\\slash"`},
+		{`quo"te`, `"quo\"te"`},
+		{`\"both\"`, `"\\\"both\\\""`},
+	}
+	for _, tt := range tests {
+		if got := quoteRegString(tt.in); got != tt.want {
+			t.Errorf("quoteRegString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// quoteRegString always wraps its result in the quotes
+		// unquoteRegString expects to have already been stripped.
+		quoted := tt.want[1 : len(tt.want)-1]
+		if got := unquoteRegString(quoted); got != tt.in {
+			t.Errorf("unquoteRegString(%q) = %q, want %q", quoted, got, tt.in)
+		}
+	}
+}
+
+func TestParseHexBytes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []byte
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"00", []byte{0x00}, false},
+		{"01,02,03", []byte{0x01, 0x02, 0x03}, false},
+		{"01,02,", []byte{0x01, 0x02}, false},
+		{"ff,  00, 7f", []byte{0xff, 0x00, 0x7f}, false},
+		{"zz", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseHexBytes(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHexBytes(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHexBytes(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJoinContinuations(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{
+			in:   []string{`"a"=hex:01,02`},
+			want: []string{`"a"=hex:01,02`},
+		},
+		{
+			in:   []string{`"a"=hex:01,\`, `  02,03`},
+			want: []string{`"a"=hex:01,02,03`},
+		},
+		{
+			in:   []string{`"a"=hex:01,\`, `  02,\`, `  03`},
+			want: []string{`"a"=hex:01,02,03`},
+		},
+		{
+			in:   []string{`"a"=hex:01,02`, `"b"=dword:00000001`},
+			want: []string{`"a"=hex:01,02`, `"b"=dword:00000001`},
+		},
+	}
+	for _, tt := range tests {
+		if got := joinContinuations(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("joinContinuations(%#v) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteHexValueWraps(t *testing.T) {
+	data := make([]byte, 64)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	var b strings.Builder
+	writeHexValue(&b, "hex:", data)
+	out := b.String()
+
+	if !strings.Contains(out, "\\\r\n  ") {
+		t.Fatalf("writeHexValue output has no continuation line:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimRight(out, "\r\n"), "\r\n") {
+		if l := len(line); l > regLineWidth {
+			t.Errorf("line %q has length %d, want <= %d", line, l, regLineWidth)
+		}
+	}
+
+	// The tokens must round-trip back to the original bytes once
+	// continuations are rejoined and reparsed.
+	joined := joinContinuations(strings.Split(strings.TrimRight(out, "\r\n"), "\n"))
+	if len(joined) != 1 {
+		t.Fatalf("joinContinuations produced %d lines, want 1", len(joined))
+	}
+	got, err := parseHexBytes(strings.TrimPrefix(joined[0], "hex:"))
+	if err != nil {
+		t.Fatalf("parseHexBytes: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("round-tripped bytes = %#v, want %#v", got, data)
+	}
+}
+
+func TestWriteHexValueNoWrapWhenShort(t *testing.T) {
+	var b strings.Builder
+	writeHexValue(&b, "hex:", []byte{0x01, 0x02, 0x03})
+	if strings.Contains(b.String(), "\\\r\n") {
+		t.Errorf("writeHexValue wrapped a short value: %q", b.String())
+	}
+}
+
+func TestDecodeRegSZ(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{
+			name: "hi with trailing NUL",
+			in:   []byte{'h', 0, 'i', 0, 0, 0},
+			want: "hi",
+		},
+		{
+			name: "no trailing NUL",
+			in:   []byte{'h', 0, 'i', 0},
+			want: "hi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeRegSZ(tt.in); got != tt.want {
+				t.Errorf("decodeRegSZ(%#v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
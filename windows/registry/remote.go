@@ -0,0 +1,129 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// netResource mirrors the Win32 NETRESOURCEW structure, used to describe
+// the IPC$ share ConnectWithCredentials authenticates against before
+// connecting to the remote registry.
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+const resourcetypeAny = 0
+
+// remoteValidRoots lists the only predefined keys the remote registry
+// service accepts; every other predefined key fails RegConnectRegistry
+// with ERROR_INVALID_PARAMETER.
+var remoteValidRoots = map[Key]bool{
+	LOCAL_MACHINE:    true,
+	USERS:            true,
+	PERFORMANCE_DATA: true,
+	CURRENT_CONFIG:   true,
+}
+
+// ValidRemoteRoot reports whether hk is one of the predefined keys that
+// can be passed to Connect or ConnectWithCredentials: HKEY_LOCAL_MACHINE,
+// HKEY_USERS, HKEY_PERFORMANCE_DATA, or HKEY_CURRENT_CONFIG.
+func ValidRemoteRoot(hk Key) bool {
+	return remoteValidRoots[hk]
+}
+
+// RemoteKey is a Key opened on a remote machine through Connect or
+// ConnectWithCredentials. Every method of Key is available unchanged
+// through embedding, and Close additionally tears down any network
+// connection ConnectWithCredentials set up to authenticate the call.
+//
+// Package-level functions that take a Key, such as OpenKey, CreateKey,
+// and DeleteKey, are not methods and so are not promoted by embedding;
+// pass rk.Key to them explicitly.
+type RemoteKey struct {
+	Key
+	unc string
+}
+
+// Connect opens the predefined key hk on machine, which can be given as
+// a bare hostname or a UNC-style `\\host` name, using RegConnectRegistry.
+// Only LOCAL_MACHINE, USERS, PERFORMANCE_DATA, and CURRENT_CONFIG are
+// valid remotely; any other hk is rejected before making the call.
+func Connect(machine string, hk Key) (RemoteKey, error) {
+	if !ValidRemoteRoot(hk) {
+		return RemoteKey{}, fmt.Errorf("registry: %v is not a valid remote root key", hk)
+	}
+	pmachine, err := syscall.UTF16PtrFromString(machine)
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	var result syscall.Handle
+	if err := regConnectRegistry(pmachine, syscall.Handle(hk), &result); err != nil {
+		return RemoteKey{}, err
+	}
+	return RemoteKey{Key: Key(result)}, nil
+}
+
+// ConnectWithCredentials is like Connect, but first authenticates to
+// \\machine\IPC$ as user with password via WNetAddConnection2, the same
+// mechanism `net use` relies on. The credentialed connection is torn
+// down by RemoteKey.Close, alongside the registry handle itself.
+func ConnectWithCredentials(machine, user, password string, hk Key) (RemoteKey, error) {
+	unc := `\\` + strings.TrimPrefix(machine, `\\`) + `\IPC$`
+	puser, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	ppassword, err := syscall.UTF16PtrFromString(password)
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	premote, err := syscall.UTF16PtrFromString(unc)
+	if err != nil {
+		return RemoteKey{}, err
+	}
+	nr := netResource{Type: resourcetypeAny, RemoteName: premote}
+	if err := wNetAddConnection2(&nr, ppassword, puser, 0); err != nil {
+		return RemoteKey{}, err
+	}
+
+	k, err := Connect(machine, hk)
+	if err != nil {
+		wNetCancelConnection2(premote, 0, true)
+		return RemoteKey{}, err
+	}
+	k.unc = unc
+	return k, nil
+}
+
+// Close closes the remote key and, if the connection was established by
+// ConnectWithCredentials, disconnects the underlying IPC$ session.
+func (rk RemoteKey) Close() error {
+	err := rk.Key.Close()
+	if rk.unc != "" {
+		punc, uncErr := syscall.UTF16PtrFromString(rk.unc)
+		if uncErr != nil {
+			if err == nil {
+				err = uncErr
+			}
+			return err
+		}
+		if discErr := wNetCancelConnection2(punc, 0, true); discErr != nil && err == nil {
+			err = discErr
+		}
+	}
+	return err
+}
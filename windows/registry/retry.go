@@ -0,0 +1,179 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"math/rand"
+	"syscall"
+	"time"
+)
+
+// Well-known transient Win32/RPC errors. ERROR_BUSY and
+// ERROR_SHARING_VIOLATION show up when another process briefly holds the
+// key; ERROR_LOCK_FAILED comes from policy-locked keys; and
+// RPC_S_SERVER_UNAVAILABLE surfaces when a remote registry connection
+// opened with Connect drops mid-call.
+const (
+	errnoBusy                 = syscall.Errno(170)
+	errnoSharingViolation     = syscall.Errno(32)
+	errnoLockFailed           = syscall.Errno(167)
+	errnoRPCServerUnavailable = syscall.Errno(1722)
+)
+
+// RetryPolicy controls how a RetryKey retries operations that fail with a
+// transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times an operation is tried,
+	// including the first attempt. It defaults to 5 if zero or negative.
+	MaxAttempts int
+	// BaseBackoff is the delay before the second attempt; it doubles
+	// after every subsequent retry. It defaults to 50ms if zero or
+	// negative.
+	BaseBackoff time.Duration
+	// Jitter adds a random delay in [0, Jitter) on top of BaseBackoff to
+	// avoid many callers retrying in lock-step.
+	Jitter time.Duration
+	// Retryable reports whether err should trigger a retry, in addition
+	// to the default set of known-transient errors (ERROR_BUSY,
+	// ERROR_SHARING_VIOLATION, ERROR_LOCK_FAILED, and
+	// RPC_S_SERVER_UNAVAILABLE). It may be nil.
+	Retryable func(error) bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = 50 * time.Millisecond
+	}
+	return p
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	if p.Retryable != nil && p.Retryable(err) {
+		return true
+	}
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+	switch errno {
+	case errnoBusy, errnoSharingViolation, errnoLockFailed, errnoRPCServerUnavailable:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) do(fn func() error) error {
+	p = p.withDefaults()
+	var err error
+	for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !p.isRetryable(err) {
+			return err
+		}
+		if attempt == p.MaxAttempts-1 {
+			break
+		}
+		backoff := p.BaseBackoff * time.Duration(int64(1)<<uint(attempt))
+		if p.Jitter > 0 {
+			backoff += time.Duration(rand.Int63n(int64(p.Jitter)))
+		}
+		time.Sleep(backoff)
+	}
+	return err
+}
+
+// RetryKey decorates a Key so that GetValue, the Set*Value family,
+// DeleteValue, ReadValueNames, and ReadSubKeyNames retry on transient
+// errors according to Policy. Every other method, including Close, is
+// inherited unchanged from the embedded Key. A RetryKey is not itself a
+// Key: functions that take a Key must be passed rk.Key, and calls made
+// through it do not retry.
+type RetryKey struct {
+	Key
+	Policy RetryPolicy
+}
+
+// WithRetry wraps k so that its transient-error-prone operations retry
+// according to p. It is a thin decorator: the underlying handle and every
+// other method are unchanged, so existing code that only needs a subset
+// of retrying calls does not need to change anything else.
+func (k Key) WithRetry(p RetryPolicy) RetryKey {
+	return RetryKey{Key: k, Policy: p}
+}
+
+// GetValue is like Key.GetValue but retries on transient errors.
+func (rk RetryKey) GetValue(name string, buf []byte) (n int, valtype uint32, err error) {
+	err = rk.Policy.do(func() error {
+		var e error
+		n, valtype, e = rk.Key.GetValue(name, buf)
+		return e
+	})
+	return
+}
+
+// SetDWordValue is like Key.SetDWordValue but retries on transient errors.
+func (rk RetryKey) SetDWordValue(name string, value uint32) error {
+	return rk.Policy.do(func() error { return rk.Key.SetDWordValue(name, value) })
+}
+
+// SetQWordValue is like Key.SetQWordValue but retries on transient errors.
+func (rk RetryKey) SetQWordValue(name string, value uint64) error {
+	return rk.Policy.do(func() error { return rk.Key.SetQWordValue(name, value) })
+}
+
+// SetStringValue is like Key.SetStringValue but retries on transient errors.
+func (rk RetryKey) SetStringValue(name, value string) error {
+	return rk.Policy.do(func() error { return rk.Key.SetStringValue(name, value) })
+}
+
+// SetExpandStringValue is like Key.SetExpandStringValue but retries on
+// transient errors.
+func (rk RetryKey) SetExpandStringValue(name, value string) error {
+	return rk.Policy.do(func() error { return rk.Key.SetExpandStringValue(name, value) })
+}
+
+// SetStringsValue is like Key.SetStringsValue but retries on transient
+// errors.
+func (rk RetryKey) SetStringsValue(name string, value []string) error {
+	return rk.Policy.do(func() error { return rk.Key.SetStringsValue(name, value) })
+}
+
+// SetBinaryValue is like Key.SetBinaryValue but retries on transient
+// errors.
+func (rk RetryKey) SetBinaryValue(name string, value []byte) error {
+	return rk.Policy.do(func() error { return rk.Key.SetBinaryValue(name, value) })
+}
+
+// DeleteValue is like Key.DeleteValue but retries on transient errors.
+func (rk RetryKey) DeleteValue(name string) error {
+	return rk.Policy.do(func() error { return rk.Key.DeleteValue(name) })
+}
+
+// ReadValueNames is like Key.ReadValueNames but retries on transient
+// errors.
+func (rk RetryKey) ReadValueNames(n int) (names []string, err error) {
+	err = rk.Policy.do(func() error {
+		var e error
+		names, e = rk.Key.ReadValueNames(n)
+		return e
+	})
+	return
+}
+
+// ReadSubKeyNames is like Key.ReadSubKeyNames but retries on transient
+// errors.
+func (rk RetryKey) ReadSubKeyNames(n int) (names []string, err error) {
+	err = rk.Policy.do(func() error {
+		var e error
+		names, e = rk.Key.ReadSubKeyNames(n)
+		return e
+	})
+	return
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		err    error
+		want   bool
+	}{
+		{"nil error", RetryPolicy{}, nil, false},
+		{"ERROR_BUSY", RetryPolicy{}, errnoBusy, true},
+		{"ERROR_SHARING_VIOLATION", RetryPolicy{}, errnoSharingViolation, true},
+		{"ERROR_LOCK_FAILED", RetryPolicy{}, errnoLockFailed, true},
+		{"RPC_S_SERVER_UNAVAILABLE", RetryPolicy{}, errnoRPCServerUnavailable, true},
+		{"unrelated errno", RetryPolicy{}, syscall.Errno(2), false},
+		{"non-errno error", RetryPolicy{}, errors.New("boom"), false},
+		{
+			name:   "custom predicate overrides default false",
+			policy: RetryPolicy{Retryable: func(error) bool { return true }},
+			err:    errors.New("boom"),
+			want:   true,
+		},
+		{
+			name:   "custom predicate does not suppress default true",
+			policy: RetryPolicy{Retryable: func(error) bool { return false }},
+			err:    errnoBusy,
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoRetriesTransientErrors(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, BaseBackoff: time.Microsecond}
+	attempts := 0
+	err := p.do(func() error {
+		attempts++
+		if attempts < 3 {
+			return errnoBusy
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("do() error = %v, want nil", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryPolicyDoGivesUpAfterMaxAttempts(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Microsecond}
+	attempts := 0
+	err := p.do(func() error {
+		attempts++
+		return errnoBusy
+	})
+	if err != errnoBusy {
+		t.Fatalf("do() error = %v, want %v", err, errnoBusy)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryPolicyDoDoesNotRetryNonTransientErrors(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Microsecond}
+	wantErr := errors.New("permanent failure")
+	attempts := 0
+	err := p.do(func() error {
+		attempts++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("do() error = %v, want %v", err, wantErr)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (no retries for non-transient errors)", attempts)
+	}
+}
+
+func TestRetryPolicyWithDefaults(t *testing.T) {
+	p := RetryPolicy{}.withDefaults()
+	if p.MaxAttempts <= 0 {
+		t.Errorf("MaxAttempts = %d, want > 0", p.MaxAttempts)
+	}
+	if p.BaseBackoff <= 0 {
+		t.Errorf("BaseBackoff = %v, want > 0", p.BaseBackoff)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import "syscall"
+
+// _ERROR_NO_MORE_ITEMS is returned by RegEnumValue/RegEnumKeyEx once the
+// enumeration has passed the last item.
+const _ERROR_NO_MORE_ITEMS syscall.Errno = 259
+
+//go:generate go run golang.org/x/sys/windows/mkwinsyscall -output zsyscall_windows.go syscall.go
+
+//sys	createEvent(sa *syscall.SecurityAttributes, manualReset bool, initialState bool, name *uint16) (handle syscall.Handle, err error) = kernel32.CreateEventW
+//sys	regNotifyChangeKeyValue(key syscall.Handle, watchSubtree bool, notifyFilter uint32, event syscall.Handle, asynchronous bool) (regerrno error) = advapi32.RegNotifyChangeKeyValue
+//sys	waitForMultipleObjects(handles []syscall.Handle, waitAll bool, waitMilliseconds uint32) (event uint32, err error) [failretval==0xffffffff] = kernel32.WaitForMultipleObjects
+//sys	setEvent(event syscall.Handle) (err error) = kernel32.SetEvent
+//sys	resetEvent(event syscall.Handle) (err error) = kernel32.ResetEvent
+//sys	createTransaction(sa *syscall.SecurityAttributes, uow *syscall.GUID, createOptions uint32, isolationLevel uint32, isolationFlags uint32, timeout uint32, description *uint16) (handle syscall.Handle, err error) [failretval==syscall.InvalidHandle] = ktmw32.CreateTransaction
+//sys	commitTransaction(transaction syscall.Handle) (err error) = ktmw32.CommitTransaction
+//sys	rollbackTransaction(transaction syscall.Handle) (err error) = ktmw32.RollbackTransaction
+//sys	regCreateKeyTransacted(key syscall.Handle, subkey *uint16, reserved uint32, class *uint16, options uint32, desired uint32, sa *syscall.SecurityAttributes, result *syscall.Handle, disposition *uint32, transaction syscall.Handle, extendedParameter *uintptr) (regerrno error) = advapi32.RegCreateKeyTransactedW
+//sys	regOpenKeyTransacted(key syscall.Handle, subkey *uint16, options uint32, desired uint32, result *syscall.Handle, transaction syscall.Handle, extendedParameter *uintptr) (regerrno error) = advapi32.RegOpenKeyTransactedW
+//sys	regDeleteKeyTransacted(key syscall.Handle, subkey *uint16, desired uint32, reserved uint32, transaction syscall.Handle, extendedParameter *uintptr) (regerrno error) = advapi32.RegDeleteKeyTransactedW
+//sys	regConnectRegistry(machineName *uint16, key syscall.Handle, result *syscall.Handle) (regerrno error) = advapi32.RegConnectRegistryW
+//sys	wNetAddConnection2(netResource *netResource, password *uint16, username *uint16, flags uint32) (neterrno error) = mpr.WNetAddConnection2W
+//sys	wNetCancelConnection2(name *uint16, flags uint32, force bool) (neterrno error) = mpr.WNetCancelConnection2W
@@ -0,0 +1,98 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package registry
+
+import "syscall"
+
+// Transaction groups a set of key and value operations so they are applied
+// to the registry atomically: either every operation takes effect, or (on
+// Rollback, or if the process dies before Commit) none of them do. It
+// wraps the kernel transaction manager (KTM) via CreateTransaction,
+// CommitTransaction, and RollbackTransaction from ktmw32.dll.
+//
+// Only opening, creating, and deleting keys need transaction-aware entry
+// points; once a Key has been obtained through the transaction, every
+// other method on Key, including Set*Value and DeleteValue, already works
+// against it unchanged because the resulting handle is ordinary registry
+// handle whose writes the kernel associates with the transaction.
+type Transaction struct {
+	handle syscall.Handle
+}
+
+// BeginTx creates a new kernel transaction. The transaction is rolled back
+// automatically by the system if the process exits before Commit is
+// called.
+func BeginTx() (*Transaction, error) {
+	h, err := createTransaction(nil, nil, 0, 0, 0, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{handle: h}, nil
+}
+
+// Commit makes every write performed through keys opened with this
+// transaction permanent.
+func (tx *Transaction) Commit() error {
+	return commitTransaction(tx.handle)
+}
+
+// Rollback discards every write performed through keys opened with this
+// transaction.
+func (tx *Transaction) Rollback() error {
+	return rollbackTransaction(tx.handle)
+}
+
+// Close releases the transaction handle without committing or rolling
+// back. Callers should normally call Commit or Rollback instead; Close is
+// provided so a Transaction can be used with defer as a safety net.
+func (tx *Transaction) Close() error {
+	return syscall.CloseHandle(tx.handle)
+}
+
+// CreateKey creates a key named path under the predefined key base, or
+// opens it if it already exists, as part of tx. It is the transacted
+// equivalent of CreateKey.
+func (tx *Transaction) CreateKey(base Key, path string, access uint32) (newk Key, openedExisting bool, err error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false, err
+	}
+	var h syscall.Handle
+	var disposition uint32
+	err = regCreateKeyTransacted(syscall.Handle(base), p, 0, nil, 0, access, nil, &h, &disposition, tx.handle, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	const regOpenedExistingKey = 2
+	return Key(h), disposition == regOpenedExistingKey, nil
+}
+
+// OpenKey opens path under the predefined key base as part of tx. It is
+// the transacted equivalent of OpenKey.
+func (tx *Transaction) OpenKey(base Key, path string, access uint32) (Key, error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var h syscall.Handle
+	err = regOpenKeyTransacted(syscall.Handle(base), p, 0, access, &h, tx.handle, nil)
+	if err != nil {
+		return 0, err
+	}
+	return Key(h), nil
+}
+
+// DeleteKey deletes path under the predefined key base as part of tx. It
+// is the transacted equivalent of DeleteKey and, like it, requires
+// path to have no subkeys.
+func (tx *Transaction) DeleteKey(base Key, path string) error {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	return regDeleteKeyTransacted(syscall.Handle(base), p, 0, 0, tx.handle, nil)
+}